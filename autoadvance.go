@@ -0,0 +1,94 @@
+package clock
+
+import "time"
+
+// Elapsed returns the simulated time that has passed since the clock was created.
+func (f *FakeClock) Elapsed() time.Duration {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return f.now.Sub(f.start)
+}
+
+// StartAutoAdvance starts advancing the clock in the background once every interval of real time. By default each
+// step advances the clock by interval; AccelerateOverTime and SetMaxAdvance change the step size. It panics if auto
+// advance is already running.
+func (f *FakeClock) StartAutoAdvance(interval time.Duration) {
+	if interval <= 0 {
+		panic("interval must be positive")
+	}
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if f.autoStop != nil {
+		panic("auto advance is already running")
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	f.autoStop = stop
+	f.autoDone = done
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				f.Advance(f.autoAdvanceStep(interval))
+			}
+		}
+	}()
+}
+
+// StopAutoAdvance stops background advancing and waits for any in-progress step to finish. It has no effect if auto
+// advance is not running.
+func (f *FakeClock) StopAutoAdvance() {
+	f.mux.Lock()
+	stop, done := f.autoStop, f.autoDone
+	f.autoStop, f.autoDone = nil, nil
+	f.mux.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+// AccelerateOverTime makes each auto advance step equal to the interval multiplied by fn(Elapsed()), so that long
+// simulations can start slowly and speed up. Steps are still capped by SetMaxAdvance, which bounds how far a single
+// step can jump however large the multiplier grows. Negative multipliers are treated as zero. Passing nil restores a
+// constant multiplier of 1.
+func (f *FakeClock) AccelerateOverTime(fn func(elapsed time.Duration) float64) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.rateFn = fn
+}
+
+// SetMaxAdvance caps the size of a single auto advance step. A value of zero removes the cap.
+func (f *FakeClock) SetMaxAdvance(d time.Duration) {
+	if d < 0 {
+		panic("max advance cannot be negative")
+	}
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.maxAdvance = d
+}
+
+func (f *FakeClock) autoAdvanceStep(interval time.Duration) time.Duration {
+	f.mux.Lock()
+	rateFn, maxAdvance, elapsed := f.rateFn, f.maxAdvance, f.now.Sub(f.start)
+	f.mux.Unlock()
+
+	// fn is called without the lock held, so it may use the clock.
+	rate := 1.0
+	if rateFn != nil {
+		rate = max(rateFn(elapsed), 0)
+	}
+	step := time.Duration(float64(interval) * rate)
+	if maxAdvance > 0 && step > maxAdvance {
+		step = maxAdvance
+	}
+	return step
+}
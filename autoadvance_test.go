@@ -0,0 +1,69 @@
+package clock_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/plan42-ai/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccelerateOverTime(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	c.SetMaxAdvance(time.Millisecond * 500)
+
+	var mux sync.Mutex
+	var observed []time.Duration
+	c.AccelerateOverTime(
+		func(elapsed time.Duration) float64 {
+			mux.Lock()
+			defer mux.Unlock()
+			observed = append(observed, elapsed)
+			if elapsed < time.Millisecond*5 {
+				return 1
+			}
+			return 1000
+		},
+	)
+	c.StartAutoAdvance(time.Millisecond)
+	require.Eventually(
+		t,
+		func() bool {
+			return c.Elapsed() >= time.Second*2
+		},
+		5*time.Second,
+		time.Millisecond,
+	)
+	c.StopAutoAdvance()
+
+	// each observation is made before a step, so the gaps between them are the step sizes.
+	mux.Lock()
+	defer mux.Unlock()
+	require.Equal(t, time.Duration(0), observed[0])
+	for i := 1; i < len(observed); i++ {
+		expected := time.Millisecond
+		if observed[i-1] >= time.Millisecond*5 {
+			// accelerated to 1000x, but capped by SetMaxAdvance.
+			expected = time.Millisecond * 500
+		}
+		require.Equal(t, expected, observed[i]-observed[i-1])
+	}
+	require.Equal(t, observed[len(observed)-1], c.Elapsed()-time.Millisecond*500)
+}
+
+func TestAutoAdvanceDefaultRate(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	timer := c.NewTimer(time.Millisecond * 3)
+	c.StartAutoAdvance(time.Millisecond)
+	<-timer.C()
+	c.StopAutoAdvance()
+	c.StopAutoAdvance()
+
+	elapsed := c.Elapsed()
+	require.Equal(t, time.Duration(0), elapsed%time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	require.Equal(t, elapsed, c.Elapsed())
+}
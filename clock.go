@@ -2,6 +2,9 @@ package clock
 
 import (
 	"context"
+	"maps"
+	"math/rand/v2"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -52,11 +55,53 @@ func NewRealClock() *RealClock {
 	return &RealClock{}
 }
 
+// FireRecord identifies a timer that fired on a FakeClock.
+type FireRecord struct {
+	ID      int64
+	Trigger time.Time
+}
+
+// ContextRecord identifies a context created by a FakeClock that became done.
+type ContextRecord struct {
+	Deadline time.Time
+	At       time.Time
+	Err      error
+}
+
+const defaultShutdownOrderLimit = 1024
+
 type FakeClock struct {
 	mux           sync.Mutex
+	start         time.Time
 	now           time.Time
+	tickDuration  time.Duration
+	fireErr       error
+	contexts      map[*FakeDeadlineContext]struct{}
+	rng           *rand.Rand
+	logFires      bool
+	armFailure    float64
+	classWeights  map[int]int
+	marks         map[string]time.Time
+	spurious      float64
+	rateFn        func(elapsed time.Duration) float64
+	maxAdvance    time.Duration
+	autoStop      chan struct{}
+	autoDone      chan struct{}
+	fireLog       []FireRecord
 	pendingTimers *persistent.SetEx[*FakeTimer]
 	nextID        atomic.Int64
+
+	resetCount atomic.Int64
+
+	callbackMux       sync.Mutex
+	callbacksInFlight int
+	callbacksIdle     chan struct{}
+
+	logShutdown   atomic.Bool
+	shutdownMux   sync.Mutex
+	shutdownLog   []any
+	shutdownNext  int
+	shutdownLimit int
 }
 
 func (f *FakeClock) Now() time.Time {
@@ -68,45 +113,494 @@ func (f *FakeClock) Now() time.Time {
 func (f *FakeClock) NewTimer(d time.Duration) Timer {
 	f.mux.Lock()
 	defer f.mux.Unlock()
+	return f.newTimer(d, 1)
+}
+
+// NewTimerWithClass is like NewTimer, but assigns the timer to a scheduling class. Classes only affect firing order
+// once weights have been configured with SetClassWeights.
+func (f *FakeClock) NewTimerWithClass(d time.Duration, class int) Timer {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	ret := f.newTimer(d, 1)
+	ret.(*FakeTimer).class = class
+	return ret
+}
+
+// SetClassWeights enables weighted fair scheduling. Among timers due at the same instant, each round fires up to
+// weights[class] timers from every class in ascending class order, in id order within a class, until all have fired.
+// Classes missing from weights, or with a weight below 1, have a weight of 1. Passing nil restores the default
+// behavior of firing coincident timers strictly in creation order.
+func (f *FakeClock) SetClassWeights(weights map[int]int) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.classWeights = maps.Clone(weights)
+}
+
+// NewTimerBuffered is like NewTimer, but the timer's channel has a buffer of size buf. This allows tests to capture
+// several fires (for example, from a reset-and-refire sequence) without draining the channel in between. A buffer
+// larger than 1 diverges from the semantics of timers in the standard library.
+func (f *FakeClock) NewTimerBuffered(d time.Duration, buf int) Timer {
+	if buf < 1 {
+		panic("buffer size must be positive")
+	}
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return f.newTimer(d, buf)
+}
+
+// NewTimers creates one timer per entry in durations while holding the clock's lock only once. The returned timers
+// are in the same order as durations.
+func (f *FakeClock) NewTimers(durations []time.Duration) []Timer {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	ret := make([]Timer, 0, len(durations))
+	for _, d := range durations {
+		ret = append(ret, f.newTimer(d, 1))
+	}
+	return ret
+}
 
+func (f *FakeClock) newTimer(d time.Duration, buf int) Timer {
 	ret := &FakeTimer{
 		clock:   f,
-		c:       make(chan time.Time, 1),
+		c:       make(chan time.Time, buf),
 		trigger: f.now.Add(d),
 		id:      f.nextID.Add(1),
 	}
-	return f.addTimer(ret)
+	return f.armTimer(ret)
 }
 
 func (f *FakeClock) AfterFunc(d time.Duration, fn func()) Timer {
 	f.mux.Lock()
 	defer f.mux.Unlock()
-	return f.afterFunc(d, fn)
+	return f.armTimer(f.newAfterFuncTimer(d, fn))
 }
 
-func (f *FakeClock) afterFunc(d time.Duration, fn func()) Timer {
+// AfterFuncErr is like AfterFunc, but fn receives the error configured via SetFireError at the time the timer fires.
+func (f *FakeClock) AfterFuncErr(d time.Duration, fn func(error)) Timer {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
 	ret := &FakeTimer{
 		clock:   f,
 		c:       nil,
-		fn:      fn,
+		errFn:   fn,
 		trigger: f.now.Add(d),
 		id:      f.nextID.Add(1),
 	}
+	return f.armTimer(ret)
+}
+
+// SetFireError configures the error passed to AfterFuncErr callbacks when they fire. The default is nil.
+func (f *FakeClock) SetFireError(err error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.fireErr = err
+}
+
+// afterFunc creates a timer for internal use that runs fn synchronously when it fires, while the clock's lock is
+// held. fn must not block or call back into the clock.
+func (f *FakeClock) afterFunc(d time.Duration, fn func()) Timer {
+	ret := f.newAfterFuncTimer(d, fn)
+	ret.inline = true
 	return f.addTimer(ret)
 }
 
+func (f *FakeClock) newAfterFuncTimer(d time.Duration, fn func()) *FakeTimer {
+	return &FakeTimer{
+		clock:   f,
+		c:       nil,
+		fn:      fn,
+		trigger: f.now.Add(d),
+		id:      f.nextID.Add(1),
+	}
+}
+
+// ActiveCallbacks returns the number of AfterFunc callbacks that are currently executing. A callback counts as active
+// from the moment its timer fires until it returns, so this is the same count reported by CallbacksInFlight.
+func (f *FakeClock) ActiveCallbacks() int {
+	return f.CallbacksInFlight()
+}
+
+// CallbacksInFlight returns the number of AfterFunc callbacks that have been triggered but have not yet returned.
+func (f *FakeClock) CallbacksInFlight() int {
+	f.callbackMux.Lock()
+	defer f.callbackMux.Unlock()
+	return f.callbacksInFlight
+}
+
+// WaitCallbacks blocks until every triggered AfterFunc callback has returned, or until ctx is done. Contexts created by
+// WithTimeout do not need to be waited for, because their deadlines are applied synchronously by Advance.
+func (f *FakeClock) WaitCallbacks(ctx context.Context) error {
+	f.callbackMux.Lock()
+	if f.callbacksInFlight == 0 {
+		f.callbackMux.Unlock()
+		return nil
+	}
+	idle := f.callbacksIdle
+	f.callbackMux.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (f *FakeClock) callbackStarted() {
+	f.callbackMux.Lock()
+	defer f.callbackMux.Unlock()
+	if f.callbacksInFlight == 0 {
+		f.callbacksIdle = make(chan struct{})
+	}
+	f.callbacksInFlight++
+}
+
+func (f *FakeClock) callbackFinished() {
+	f.callbackMux.Lock()
+	defer f.callbackMux.Unlock()
+	f.callbacksInFlight--
+	if f.callbacksInFlight == 0 {
+		close(f.callbacksIdle)
+	}
+}
+
+// ResetCount returns the total number of times Reset has been called on timers created by the clock.
+func (f *FakeClock) ResetCount() int64 {
+	return f.resetCount.Load()
+}
+
 func (f *FakeClock) Advance(d time.Duration) {
+	f.advance(d)
+}
+
+// AdvanceDidFire is like Advance, but reports whether any timer fired.
+func (f *FakeClock) AdvanceDidFire(d time.Duration) bool {
+	return f.advance(d) > 0
+}
+
+func (f *FakeClock) advance(d time.Duration) int {
 	f.mux.Lock()
 	defer f.mux.Unlock()
 	if d < 0 {
 		panic("time cannot move backwards")
 	}
 	f.now = f.now.Add(d)
+	fired := f.fireDue(f.now)
+	f.deliverSpuriousWakeups()
+	return fired
+}
 
-	for timer, ok := f.pendingTimers.GetKthElement(0); ok && !timer.trigger.After(f.now); timer, ok = f.pendingTimers.GetKthElement(0) {
-		f.pendingTimers = f.pendingTimers.Remove(timer)
-		timer.fire()
+// SetSpuriousWakeups is a robustness testing feature that deliberately violates normal timer semantics. When enabled,
+// each Advance gives every pending channel timer a chance, with probability prob, of receiving an extra value carrying
+// the current time before its actual trigger. This verifies that consumers re-check their conditions rather than
+// trusting a single wakeup. Wakeups are drawn from the clock's seeded random number generator, so they are
+// reproducible. Spurious values are only delivered to timers whose channels are empty, and an unread spurious value
+// is replaced by the real one when the timer fires.
+func (f *FakeClock) SetSpuriousWakeups(prob float64) {
+	if prob < 0 || prob > 1 {
+		panic("probability must be between 0 and 1")
 	}
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.spurious = prob
+}
+
+func (f *FakeClock) deliverSpuriousWakeups() {
+	if f.spurious == 0 {
+		return
+	}
+	for iter := f.pendingTimers.Iter(); iter.Next(); {
+		timer := iter.Current()
+		// only wake timers with empty channels, so that fire knows any buffered value is the spurious one.
+		if timer.c == nil || len(timer.c) > 0 || f.random().Float64() >= f.spurious {
+			continue
+		}
+		timer.c <- f.now
+		timer.spuriousPending = true
+	}
+}
+
+// fireDue fires every pending timer whose trigger is at or before until, and returns the number of timers fired.
+func (f *FakeClock) fireDue(until time.Time) int {
+	fired := 0
+	for timer, ok := f.pendingTimers.GetKthElement(0); ok && !timer.trigger.After(until); timer, ok = f.pendingTimers.GetKthElement(0) {
+		if f.classWeights == nil {
+			f.pendingTimers = f.pendingTimers.Remove(timer)
+			timer.fire()
+			fired++
+			continue
+		}
+
+		// collect every timer due at the same instant so they can be interleaved by class.
+		var batch []*FakeTimer
+		for iter := f.pendingTimers.Iter(); iter.Next() && iter.Current().trigger.Equal(timer.trigger); {
+			batch = append(batch, iter.Current())
+		}
+		for _, t := range batch {
+			f.pendingTimers = f.pendingTimers.Remove(t)
+		}
+		f.fireFair(batch)
+		fired += len(batch)
+	}
+	return fired
+}
+
+// fireEarly fires every pending timer whose trigger is at or before until, without moving the clock. Context deadline
+// timers are left pending, so that contexts never expire before their deadline.
+func (f *FakeClock) fireEarly(until time.Time) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+
+	var batch []*FakeTimer
+	for iter := f.pendingTimers.Iter(); iter.Next() && !iter.Current().trigger.After(until); {
+		if !iter.Current().inline {
+			batch = append(batch, iter.Current())
+		}
+	}
+	for _, t := range batch {
+		f.pendingTimers = f.pendingTimers.Remove(t)
+	}
+
+	if f.classWeights != nil {
+		f.fireFair(batch)
+		return
+	}
+	for _, t := range batch {
+		t.fire()
+	}
+}
+
+func (f *FakeClock) nextTrigger() (time.Time, bool) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	timer, ok := f.pendingTimers.GetKthElement(0)
+	if !ok {
+		return time.Time{}, false
+	}
+	return timer.trigger, true
+}
+
+// TimeUntilQuiescent returns how far the clock must advance for every currently pending timer to fire. It returns false
+// if no timers are pending. The result does not account for timers that are reset or created in the meantime, such as
+// timers that reschedule themselves when they fire.
+func (f *FakeClock) TimeUntilQuiescent() (time.Duration, bool) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	timer, ok := f.pendingTimers.GetKthElement(f.pendingTimers.Size() - 1)
+	if !ok {
+		return 0, false
+	}
+	return timer.trigger.Sub(f.now), true
+}
+
+// AdvanceBySeconds advances the clock n seconds, one second at a time, firing the timers due within each second and
+// then calling perSecond with the current time. Each step acquires the clock's lock and walks the pending timers, so
+// large values of n are proportionally expensive.
+func (f *FakeClock) AdvanceBySeconds(n int, perSecond func(now time.Time)) {
+	for range n {
+		f.Advance(time.Second)
+		perSecond(f.Now())
+	}
+}
+
+// fireFair fires timers that are due at the same instant in weighted round-robin order across their classes. The
+// timers in batch must be ordered by id.
+func (f *FakeClock) fireFair(batch []*FakeTimer) {
+	byClass := make(map[int][]*FakeTimer)
+	var classes []int
+	for _, t := range batch {
+		if _, ok := byClass[t.class]; !ok {
+			classes = append(classes, t.class)
+		}
+		byClass[t.class] = append(byClass[t.class], t)
+	}
+	slices.Sort(classes)
+
+	for remaining := len(batch); remaining > 0; {
+		for _, class := range classes {
+			weight := max(f.classWeights[class], 1)
+			n := min(weight, len(byClass[class]))
+			for _, t := range byClass[class][:n] {
+				t.fire()
+			}
+			byClass[class] = byClass[class][n:]
+			remaining -= n
+		}
+	}
+}
+
+// SetTickDuration configures the length of a single simulated tick used by Ticks and AdvanceTicks. The default is
+// one nanosecond.
+func (f *FakeClock) SetTickDuration(d time.Duration) {
+	if d <= 0 {
+		panic("tick duration must be positive")
+	}
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.tickDuration = d
+}
+
+func (f *FakeClock) TickDuration() time.Duration {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return f.tick()
+}
+
+// Ticks returns the number of whole ticks that have elapsed since the clock was created.
+func (f *FakeClock) Ticks() int64 {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return int64(f.now.Sub(f.start) / f.tick())
+}
+
+// tick returns the configured tick duration, treating the zero value as one nanosecond.
+func (f *FakeClock) tick() time.Duration {
+	if f.tickDuration == 0 {
+		return time.Nanosecond
+	}
+	return f.tickDuration
+}
+
+// AdvanceTicks advances the clock by n ticks.
+func (f *FakeClock) AdvanceTicks(n int64) {
+	f.Advance(time.Duration(n) * f.TickDuration())
+}
+
+// SetSeed re-seeds the random number generator used by the clock's randomized features, such as AdvanceRandomly.
+// Clocks seeded with the same value behave identically.
+func (f *FakeClock) SetSeed(seed uint64) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.rng = newRand(seed)
+}
+
+// random returns the clock's random number generator, seeding it with 0 if SetSeed has not been called. The caller
+// must hold the clock's lock.
+func (f *FakeClock) random() *rand.Rand {
+	if f.rng == nil {
+		f.rng = newRand(0)
+	}
+	return f.rng
+}
+
+// AdvanceRandomly advances the clock by total, split into the given number of randomly sized steps, firing timers at
+// each step. The step sizes are drawn from the clock's seeded random number generator and always sum to total.
+func (f *FakeClock) AdvanceRandomly(total time.Duration, steps int) {
+	if total < 0 {
+		panic("time cannot move backwards")
+	}
+	if steps < 1 {
+		panic("steps must be positive")
+	}
+
+	f.mux.Lock()
+	cuts := make([]time.Duration, steps-1)
+	for i := range cuts {
+		cuts[i] = time.Duration(f.random().Uint64N(uint64(total) + 1))
+	}
+	f.mux.Unlock()
+	slices.Sort(cuts)
+
+	var prev time.Duration
+	for _, cut := range cuts {
+		f.Advance(cut - prev)
+		prev = cut
+	}
+	f.Advance(total - prev)
+}
+
+// SetFireOrderLogging enables or disables recording of the order in which timers fire. The log grows without bound
+// while enabled, so it should only be turned on for bounded scenarios.
+func (f *FakeClock) SetFireOrderLogging(enabled bool) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.logFires = enabled
+}
+
+// FireOrderLog returns the timers fired while fire order logging was enabled, in the order they fired.
+func (f *FakeClock) FireOrderLog() []FireRecord {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return slices.Clone(f.fireLog)
+}
+
+// SetArmFailure is a chaos testing feature that makes timers created by NewTimer, AfterFunc and related methods fail to
+// arm with probability prob, modeling resource exhaustion. A timer that fails to arm never fires, and Stop and Reset
+// on it always return false. Failures are drawn from the clock's seeded random number generator, so they are
+// reproducible.
+func (f *FakeClock) SetArmFailure(prob float64) {
+	if prob < 0 || prob > 1 {
+		panic("probability must be between 0 and 1")
+	}
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.armFailure = prob
+}
+
+func (f *FakeClock) armTimer(t *FakeTimer) Timer {
+	if f.armFailure > 0 && f.random().Float64() < f.armFailure {
+		t.failed = true
+		return t
+	}
+	return f.addTimer(t)
+}
+
+// SetShutdownOrderLogging enables or disables recording of the log returned by ShutdownOrder.
+func (f *FakeClock) SetShutdownOrderLogging(enabled bool) {
+	f.logShutdown.Store(enabled)
+}
+
+// ShutdownOrder returns a chronological log of timer fires and context completions recorded while shutdown order
+// logging was enabled, useful for verifying the order of a graceful shutdown. Timer fires are recorded as FireRecord
+// values and context completions as ContextRecord values. Only the most recent entries are retained, up to the limit
+// set by SetShutdownOrderLimit (1024 by default).
+func (f *FakeClock) ShutdownOrder() []any {
+	f.shutdownMux.Lock()
+	defer f.shutdownMux.Unlock()
+	return f.orderedShutdownLog()
+}
+
+// SetShutdownOrderLimit sets the maximum number of entries retained by ShutdownOrder.
+func (f *FakeClock) SetShutdownOrderLimit(limit int) {
+	if limit < 1 {
+		panic("limit must be positive")
+	}
+	f.shutdownMux.Lock()
+	defer f.shutdownMux.Unlock()
+	entries := f.orderedShutdownLog()
+	f.shutdownLog = entries[max(len(entries)-limit, 0):]
+	f.shutdownNext = 0
+	f.shutdownLimit = limit
+}
+
+// recordShutdown appends entry to the shutdown order log, which is a ring buffer whose oldest entry is at shutdownNext
+// once it is full.
+func (f *FakeClock) recordShutdown(entry any) {
+	if !f.logShutdown.Load() {
+		return
+	}
+	f.shutdownMux.Lock()
+	defer f.shutdownMux.Unlock()
+
+	limit := f.shutdownLimit
+	if limit == 0 {
+		limit = defaultShutdownOrderLimit
+	}
+	if len(f.shutdownLog) < limit {
+		f.shutdownLog = append(f.shutdownLog, entry)
+		return
+	}
+	f.shutdownLog[f.shutdownNext] = entry
+	f.shutdownNext = (f.shutdownNext + 1) % limit
+}
+
+func (f *FakeClock) orderedShutdownLog() []any {
+	ret := make([]any, 0, len(f.shutdownLog))
+	ret = append(ret, f.shutdownLog[f.shutdownNext:]...)
+	return append(ret, f.shutdownLog[:f.shutdownNext]...)
 }
 
 func (f *FakeClock) addTimer(t *FakeTimer) Timer {
@@ -118,18 +612,21 @@ func (f *FakeClock) addTimer(t *FakeTimer) Timer {
 	return t
 }
 
+// WithTimeout returns a context that expires once the clock has been advanced by d. The deadline is applied
+// synchronously, so the context is already done when the Advance call that reaches it returns.
 func (f *FakeClock) WithTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
 	f.mux.Lock()
 	defer f.mux.Unlock()
 	ctx := &FakeDeadlineContext{
 		Context:  parent,
+		clock:    f,
 		done:     make(chan struct{}),
 		deadline: f.now.Add(d),
 	}
 
 	// If the deadline is already in the past, mark the context as deadline exceeded.
 	if d <= 0 {
-		ctx.setErrorOnce(context.DeadlineExceeded)
+		ctx.setErrorOnce(context.DeadlineExceeded, f.now)
 		return ctx, func() {
 			// already canceled
 		}
@@ -138,7 +635,7 @@ func (f *FakeClock) WithTimeout(parent context.Context, d time.Duration) (contex
 	// if the parent is already done, propagate its completion.
 	select {
 	case <-parent.Done():
-		ctx.setErrorOnce(parent.Err())
+		ctx.setErrorOnce(parent.Err(), f.now)
 		return ctx, func() {
 			// already canceled}
 		}
@@ -148,24 +645,32 @@ func (f *FakeClock) WithTimeout(parent context.Context, d time.Duration) (contex
 	// otherwise create a fake timer that trigger's deadline exceeded when it fires
 	timer := f.afterFunc(
 		d, func() {
-			ctx.setErrorOnce(context.DeadlineExceeded)
+			ctx.setErrorOnce(context.DeadlineExceeded, ctx.deadline)
 		},
 	)
+	ctx.timer = timer
+
+	// track the context so that CancelAllContexts can find it
+	if f.contexts == nil {
+		f.contexts = make(map[*FakeDeadlineContext]struct{})
+	}
+	f.contexts[ctx] = struct{}{}
 
 	// generate a proper cancel function
 	cancel := func() {
 		timer.Stop()
-		ctx.setErrorOnce(context.Canceled)
+		ctx.setErrorOnce(context.Canceled, f.Now())
 	}
 
 	// and spin up a go routine that propagates cancellation from the parent context to the new context
 	go func() {
+		defer f.forgetContext(ctx)
 		select {
 		case <-ctx.done:
 			return
 		case <-parent.Done():
 			timer.Stop()
-			ctx.setErrorOnce(parent.Err())
+			ctx.setErrorOnce(parent.Err(), f.Now())
 		}
 	}()
 
@@ -173,12 +678,74 @@ func (f *FakeClock) WithTimeout(parent context.Context, d time.Duration) (contex
 	return ctx, cancel
 }
 
+// CancelAllContexts marks every live context created by WithTimeout as done with the given cause. A nil cause is
+// treated as context.Canceled. Contexts that are already done are unaffected.
+func (f *FakeClock) CancelAllContexts(cause error) {
+	if cause == nil {
+		cause = context.Canceled
+	}
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	for ctx := range f.contexts {
+		if timer, ok := ctx.timer.(*FakeTimer); ok {
+			f.pendingTimers = f.pendingTimers.Remove(timer)
+		}
+		ctx.setErrorOnce(cause, f.now)
+	}
+}
+
+func (f *FakeClock) forgetContext(ctx *FakeDeadlineContext) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	delete(f.contexts, ctx)
+}
+
+// Mark records the current simulated time under name, replacing any earlier mark with the same name.
+func (f *FakeClock) Mark(name string) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if f.marks == nil {
+		f.marks = make(map[string]time.Time)
+	}
+	f.marks[name] = f.now
+}
+
+// Since returns the simulated time elapsed since the mark recorded under name. It returns false if there is no such
+// mark.
+func (f *FakeClock) Since(name string) (time.Duration, bool) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	mark, ok := f.marks[name]
+	if !ok {
+		return 0, false
+	}
+	return f.now.Sub(mark), true
+}
+
+// TranslateDeadline converts the deadline of ctx, expressed in f's time frame, into target's time frame by preserving
+// the time remaining until the deadline. It assumes that f and target advance together, or that any skew between them
+// is already reflected in their current times. It returns false if ctx has no deadline.
+func (f *FakeClock) TranslateDeadline(ctx context.Context, target *FakeClock) (time.Time, bool) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return time.Time{}, false
+	}
+	remaining := deadline.Sub(f.Now())
+	return target.Now().Add(remaining), true
+}
+
 type FakeTimer struct {
 	clock   *FakeClock
 	c       chan time.Time
 	fn      func()
+	errFn   func(error)
 	trigger time.Time
 	id      int64
+	failed  bool
+	class   int
+	inline  bool
+
+	spuriousPending bool
 }
 
 func (f *FakeTimer) Stop() bool {
@@ -197,6 +764,10 @@ func (f *FakeTimer) C() <-chan time.Time {
 }
 
 func (f *FakeTimer) Reset(d time.Duration) bool {
+	f.clock.resetCount.Add(1)
+	if f.failed {
+		return false
+	}
 	f.clock.mux.Lock()
 	defer f.clock.mux.Unlock()
 
@@ -210,9 +781,39 @@ func (f *FakeTimer) Reset(d time.Duration) bool {
 }
 
 func (f *FakeTimer) fire() {
-	if f.fn != nil {
-		go f.fn()
+	if f.clock.logFires {
+		f.clock.fireLog = append(f.clock.fireLog, FireRecord{ID: f.id, Trigger: f.trigger})
+	}
+
+	fn := f.fn
+	if f.errFn != nil {
+		err := f.clock.fireErr
+		fn = func() {
+			f.errFn(err)
+		}
+	}
+
+	if f.inline {
+		fn()
+		return
+	}
+
+	f.clock.recordShutdown(FireRecord{ID: f.id, Trigger: f.trigger})
+	if fn != nil {
+		f.clock.callbackStarted()
+		go func() {
+			defer f.clock.callbackFinished()
+			fn()
+		}()
 	} else {
+		if f.spuriousPending {
+			// discard an unread spurious value so the real one doesn't block.
+			select {
+			case <-f.c:
+			default:
+			}
+			f.spuriousPending = false
+		}
 		f.c <- f.trigger
 	}
 }
@@ -228,9 +829,12 @@ func (f *FakeTimer) Less(rhs *FakeTimer) bool {
 
 type FakeDeadlineContext struct {
 	context.Context
-	done     chan struct{}
-	err      atomic.Pointer[error]
-	deadline time.Time
+	clock       *FakeClock
+	done        chan struct{}
+	err         atomic.Pointer[error]
+	deadline    time.Time
+	timer       Timer
+	completedAt time.Time
 }
 
 func (ctx *FakeDeadlineContext) Deadline() (deadline time.Time, ok bool) {
@@ -254,14 +858,40 @@ func (ctx *FakeDeadlineContext) Err() error {
 	}
 }
 
-func (ctx *FakeDeadlineContext) setErrorOnce(err error) {
+func (ctx *FakeDeadlineContext) Value(key any) any {
+	if _, ok := key.(clockKey); ok {
+		return ctx.clock
+	}
+	return ctx.Context.Value(key)
+}
+
+// CompletedAt returns the simulated time at which the context became done. It returns false if the context is not done
+// yet.
+func (ctx *FakeDeadlineContext) CompletedAt() (time.Time, bool) {
+	select {
+	case <-ctx.Done():
+		return ctx.completedAt, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func (ctx *FakeDeadlineContext) setErrorOnce(err error, now time.Time) {
 	if ctx.err.CompareAndSwap(nil, &err) {
+		ctx.completedAt = now
+		ctx.clock.recordShutdown(ContextRecord{Deadline: ctx.deadline, At: now, Err: err})
 		close(ctx.done)
 	}
 }
 
 func NewFakeClock(now time.Time) *FakeClock {
 	return &FakeClock{
-		now: now,
+		start: now,
+		now:   now,
 	}
 }
+
+func newRand(seed uint64) *rand.Rand {
+	// The fake clock needs reproducible randomness, not cryptographic randomness.
+	return rand.New(rand.NewPCG(seed, seed)) //nolint:gosec
+}
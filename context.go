@@ -0,0 +1,20 @@
+package clock
+
+import "context"
+
+type clockKey struct{}
+
+// WithClock returns a copy of ctx that carries c, so that code further down the call chain can retrieve it with
+// FromContext.
+func WithClock(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, c)
+}
+
+// FromContext returns the clock carried by ctx. Contexts created by FakeClock.WithTimeout carry the FakeClock that
+// created them. As with any context value, the nearest clock wins: a clock attached with WithClock to a context derived
+// from a timeout context takes precedence over the FakeClock, while a clock attached to the timeout context's parent
+// is shadowed by it.
+func FromContext(ctx context.Context) (Clock, bool) {
+	c, ok := ctx.Value(clockKey{}).(Clock)
+	return c, ok
+}
@@ -0,0 +1,39 @@
+package clock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/plan42-ai/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+	_, ok := clock.FromContext(context.Background())
+	require.False(t, ok)
+
+	realClock := clock.NewRealClock()
+	ctx := clock.WithClock(context.Background(), realClock)
+	c, ok := clock.FromContext(ctx)
+	require.True(t, ok)
+	require.Same(t, realClock, c)
+}
+
+func TestFromContextWithTimeout(t *testing.T) {
+	t.Parallel()
+	fake := clock.NewFakeClock(theMostImportantDateEver)
+	parent := clock.WithClock(context.Background(), clock.NewRealClock())
+	ctx, cancel := fake.WithTimeout(parent, time.Minute)
+	defer cancel()
+
+	c, ok := clock.FromContext(ctx)
+	require.True(t, ok)
+	require.Same(t, fake, c)
+
+	other := clock.NewFakeClock(theMostImportantDateEver)
+	c, ok = clock.FromContext(clock.WithClock(ctx, other))
+	require.True(t, ok)
+	require.Same(t, other, c)
+}
@@ -2,6 +2,8 @@ package clock_test
 
 import (
 	"context"
+	"errors"
+	"math"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -32,20 +34,23 @@ func TestTimer(t *testing.T) {
 func TestAfterFunc(t *testing.T) {
 	t.Parallel()
 	c := clock.NewFakeClock(theMostImportantDateEver)
+	fn, assertRan := clock.OnceCallback()
 	run := atomic.Bool{}
 	timer := c.AfterFunc(
 		time.Hour*25,
 		func() {
 			run.Store(true)
+			fn()
 		},
 	)
 	c.Advance(time.Hour * 24)
 	ensureNotTriggered(t, timer)
+	require.NoError(t, c.WaitCallbacks(t.Context()))
 	require.False(t, run.Load())
 	c.Advance(time.Hour)
 	timer.Stop()
-	time.Sleep(50 * time.Millisecond)
-	require.True(t, run.Load())
+	require.NoError(t, c.WaitCallbacks(t.Context()))
+	assertRan(t)
 }
 
 func TestAfterFuncCanceled(t *testing.T) {
@@ -62,7 +67,7 @@ func TestAfterFuncCanceled(t *testing.T) {
 	ensureNotTriggered(t, timer)
 	timer.Stop()
 	c.Advance(time.Hour)
-	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, c.WaitCallbacks(t.Context()))
 	require.False(t, run.Load())
 }
 
@@ -190,3 +195,549 @@ func TestParentCanceled(t *testing.T) {
 		require.Fail(t, "cancellation did not propagate from parent context to child context")
 	}
 }
+
+func TestNewTimers(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	timers := c.NewTimers([]time.Duration{time.Hour * 3, time.Hour, time.Hour * 2})
+	require.Len(t, timers, 3)
+
+	c.Advance(time.Hour)
+	ensureNotTriggered(t, timers[0])
+	ensureTriggered(t, timers[1])
+	ensureNotTriggered(t, timers[2])
+
+	c.Advance(time.Hour)
+	ensureNotTriggered(t, timers[0])
+	ensureTriggered(t, timers[2])
+
+	c.Advance(time.Hour)
+	ensureTriggered(t, timers[0])
+}
+
+func BenchmarkNewTimers(b *testing.B) {
+	durations := make([]time.Duration, 1000)
+	for i := range durations {
+		durations[i] = time.Duration(i+1) * time.Second
+	}
+
+	b.Run(
+		"Batch", func(b *testing.B) {
+			for b.Loop() {
+				c := clock.NewFakeClock(theMostImportantDateEver)
+				c.NewTimers(durations)
+			}
+		},
+	)
+
+	b.Run(
+		"Loop", func(b *testing.B) {
+			for b.Loop() {
+				c := clock.NewFakeClock(theMostImportantDateEver)
+				for _, d := range durations {
+					c.NewTimer(d)
+				}
+			}
+		},
+	)
+}
+
+func TestActiveCallbacks(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	const callbacks = 3
+	for range callbacks {
+		c.AfterFunc(
+			time.Hour,
+			func() {
+				started <- struct{}{}
+				<-release
+			},
+		)
+	}
+	require.Equal(t, 0, c.ActiveCallbacks())
+
+	// sample the active count for the whole run; it must never exceed the number of triggered callbacks.
+	stopSampling := make(chan struct{})
+	maxActive := make(chan int)
+	go func() {
+		highest := 0
+		for {
+			highest = max(highest, c.ActiveCallbacks())
+			select {
+			case <-stopSampling:
+				maxActive <- highest
+				return
+			default:
+			}
+		}
+	}()
+
+	c.Advance(time.Hour)
+	for range callbacks {
+		<-started
+	}
+	require.Equal(t, callbacks, c.ActiveCallbacks())
+
+	close(release)
+	require.Eventually(
+		t,
+		func() bool {
+			return c.ActiveCallbacks() == 0
+		},
+		time.Second,
+		time.Millisecond,
+	)
+	close(stopSampling)
+	require.LessOrEqual(t, <-maxActive, callbacks)
+}
+
+func TestTicks(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	require.Equal(t, time.Nanosecond, c.TickDuration())
+	c.AdvanceTicks(5)
+	require.Equal(t, int64(5), c.Ticks())
+
+	c.SetTickDuration(time.Millisecond)
+	timer := c.NewTimer(time.Millisecond * 10)
+	c.AdvanceTicks(9)
+	require.Equal(t, int64(9), c.Ticks())
+	ensureNotTriggered(t, timer)
+	c.AdvanceTicks(1)
+	ensureTriggered(t, timer)
+
+	c.Advance(time.Second)
+	require.Equal(t, int64(1010), c.Ticks())
+	require.Equal(t, theMostImportantDateEver.Add(time.Millisecond*1010+time.Nanosecond*5), c.Now())
+}
+
+func TestAfterFuncErr(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	errs := make(chan error, 2)
+	c.AfterFuncErr(
+		time.Hour,
+		func(err error) {
+			errs <- err
+		},
+	)
+	c.Advance(time.Hour)
+	require.NoError(t, <-errs)
+
+	injected := errors.New("injected")
+	c.SetFireError(injected)
+	c.AfterFuncErr(
+		time.Hour,
+		func(err error) {
+			errs <- err
+		},
+	)
+	c.Advance(time.Hour)
+	require.ErrorIs(t, <-errs, injected)
+}
+
+func TestResetCount(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	first := c.NewTimer(time.Hour)
+	second := c.AfterFunc(time.Hour, func() {})
+	require.Equal(t, int64(0), c.ResetCount())
+
+	first.Reset(time.Hour)
+	first.Reset(time.Hour * 2)
+	second.Reset(time.Hour)
+	require.Equal(t, int64(3), c.ResetCount())
+}
+
+func TestTranslateDeadline(t *testing.T) {
+	t.Parallel()
+	upstream := clock.NewFakeClock(theMostImportantDateEver)
+	downstream := clock.NewFakeClock(theMostImportantDateEver.Add(time.Hour * 24 * 365))
+	ctx, cancel := upstream.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	upstream.Advance(time.Second * 15)
+	downstream.Advance(time.Second * 15)
+
+	deadline, ok := upstream.TranslateDeadline(ctx, downstream)
+	require.True(t, ok)
+	require.Equal(t, downstream.Now().Add(time.Second*45), deadline)
+
+	_, ok = upstream.TranslateDeadline(context.Background(), downstream)
+	require.False(t, ok)
+}
+
+func TestCancelAllContexts(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	done, cancelDone := c.WithTimeout(context.Background(), time.Minute)
+	defer cancelDone()
+	c.Advance(time.Minute)
+	<-done.Done()
+
+	var live []context.Context
+	for i := range 3 {
+		ctx, cancel := c.WithTimeout(context.Background(), time.Hour*time.Duration(i+1))
+		defer cancel()
+		live = append(live, ctx)
+	}
+
+	shutdown := errors.New("shutdown")
+	c.CancelAllContexts(shutdown)
+	for _, ctx := range live {
+		<-ctx.Done()
+		require.ErrorIs(t, ctx.Err(), shutdown)
+	}
+	require.ErrorIs(t, done.Err(), context.DeadlineExceeded)
+}
+
+func TestAdvanceRandomly(t *testing.T) {
+	t.Parallel()
+	run := func() []time.Time {
+		c := clock.NewFakeClock(theMostImportantDateEver)
+		c.SetSeed(42)
+		var timers []clock.Timer
+		for i := range 10 {
+			timers = append(timers, c.NewTimer(time.Minute*time.Duration(10-i)))
+		}
+		c.AdvanceRandomly(time.Hour, 7)
+		require.Equal(t, theMostImportantDateEver.Add(time.Hour), c.Now())
+
+		var fired []time.Time
+		for _, timer := range timers {
+			fired = append(fired, <-timer.C())
+		}
+		return fired
+	}
+	require.Equal(t, run(), run())
+}
+
+func TestNewTimerBuffered(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	timer := c.NewTimerBuffered(time.Hour, 3)
+	c.Advance(time.Hour)
+	timer.Reset(time.Hour)
+	c.Advance(time.Hour)
+	timer.Reset(time.Hour)
+	c.Advance(time.Hour)
+
+	for i := range 3 {
+		require.Equal(t, theMostImportantDateEver.Add(time.Hour*time.Duration(i+1)), <-timer.C())
+	}
+	ensureNotTriggered(t, timer)
+}
+
+func TestWaitCallbacks(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	require.Equal(t, 0, c.CallbacksInFlight())
+	require.NoError(t, c.WaitCallbacks(t.Context()))
+
+	release := make(chan struct{})
+	c.AfterFunc(
+		time.Hour,
+		func() {
+			<-release
+		},
+	)
+	c.Advance(time.Hour)
+	require.Equal(t, 1, c.CallbacksInFlight())
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, c.WaitCallbacks(ctx), context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, c.WaitCallbacks(t.Context()))
+	require.Equal(t, 0, c.CallbacksInFlight())
+}
+
+func TestArmFailure(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	c.SetArmFailure(1)
+	run := atomic.Bool{}
+	timer := c.NewTimer(time.Hour)
+	callback := c.AfterFunc(
+		time.Hour,
+		func() {
+			run.Store(true)
+		},
+	)
+	immediate := c.NewTimer(-time.Hour)
+	ensureNotTriggered(t, immediate)
+
+	c.Advance(time.Hour * 2)
+	require.NoError(t, c.WaitCallbacks(t.Context()))
+	ensureNotTriggered(t, timer)
+	require.False(t, run.Load())
+	require.False(t, timer.Stop())
+	require.False(t, callback.Stop())
+	require.False(t, timer.Reset(time.Hour))
+	c.Advance(time.Hour * 2)
+	ensureNotTriggered(t, timer)
+
+	c.SetArmFailure(0)
+	timer = c.NewTimer(time.Hour)
+	c.Advance(time.Hour)
+	ensureTriggered(t, timer)
+}
+
+func TestCompletedAt(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	ctx, cancel := c.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	fakeCtx, ok := ctx.(*clock.FakeDeadlineContext)
+	require.True(t, ok)
+	_, done := fakeCtx.CompletedAt()
+	require.False(t, done)
+
+	c.Advance(time.Hour)
+	<-ctx.Done()
+	completedAt, done := fakeCtx.CompletedAt()
+	require.True(t, done)
+	require.Equal(t, theMostImportantDateEver.Add(time.Minute), completedAt)
+
+	canceled, cancelCanceled := c.WithTimeout(context.Background(), time.Minute)
+	c.Advance(time.Second * 5)
+	cancelCanceled()
+	completedAt, done = canceled.(*clock.FakeDeadlineContext).CompletedAt()
+	require.True(t, done)
+	require.Equal(t, theMostImportantDateEver.Add(time.Hour+time.Second*5), completedAt)
+}
+
+func TestClassWeights(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	c.SetFireOrderLogging(true)
+	c.SetClassWeights(map[int]int{0: 2})
+	for _, class := range []int{0, 1, 0, 1, 0} {
+		c.NewTimerWithClass(time.Hour, class)
+	}
+	c.Advance(time.Hour)
+
+	var ids []int64
+	for _, record := range c.FireOrderLog() {
+		ids = append(ids, record.ID)
+	}
+	require.Equal(t, []int64{1, 3, 2, 5, 4}, ids)
+}
+
+func TestAdvanceBySeconds(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	var timers []clock.Timer
+	for _, d := range []time.Duration{500, 900, 1500, 2999, 3000} {
+		timers = append(timers, c.NewTimer(d*time.Millisecond))
+	}
+
+	var perSecond []int
+	c.AdvanceBySeconds(
+		3, func(now time.Time) {
+			fired := 0
+			for _, timer := range timers {
+				select {
+				case <-timer.C():
+					fired++
+				default:
+				}
+			}
+			perSecond = append(perSecond, fired)
+			require.Equal(t, theMostImportantDateEver.Add(time.Second*time.Duration(len(perSecond))), now)
+		},
+	)
+	require.Equal(t, []int{2, 1, 2}, perSecond)
+}
+
+func TestShutdownOrder(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	c.SetShutdownOrderLogging(true)
+	c.NewTimer(time.Minute)
+	first, cancelFirst := c.WithTimeout(context.Background(), time.Minute*2)
+	defer cancelFirst()
+	c.NewTimer(time.Minute * 3)
+	second, cancelSecond := c.WithTimeout(context.Background(), time.Minute*3)
+	defer cancelSecond()
+	c.Advance(time.Hour)
+	<-first.Done()
+	<-second.Done()
+
+	order := c.ShutdownOrder()
+	require.Len(t, order, 4)
+	require.Equal(t, theMostImportantDateEver.Add(time.Minute), order[0].(clock.FireRecord).Trigger)
+	require.Equal(t, theMostImportantDateEver.Add(time.Minute*2), order[1].(clock.ContextRecord).At)
+	require.ErrorIs(t, order[1].(clock.ContextRecord).Err, context.DeadlineExceeded)
+	require.Equal(t, theMostImportantDateEver.Add(time.Minute*3), order[2].(clock.FireRecord).Trigger)
+	require.Equal(t, theMostImportantDateEver.Add(time.Minute*3), order[3].(clock.ContextRecord).At)
+
+	c.SetShutdownOrderLimit(2)
+	require.Equal(t, order[2:], c.ShutdownOrder())
+}
+
+func TestShutdownOrderLimit(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	c.NewTimer(time.Minute)
+	c.Advance(time.Minute)
+	require.Empty(t, c.ShutdownOrder())
+
+	c.SetShutdownOrderLogging(true)
+	c.SetShutdownOrderLimit(3)
+	for i := range 5 {
+		c.NewTimer(time.Minute * time.Duration(i+1))
+	}
+	c.Advance(time.Hour)
+
+	var triggers []time.Time
+	for _, entry := range c.ShutdownOrder() {
+		triggers = append(triggers, entry.(clock.FireRecord).Trigger)
+	}
+	start := theMostImportantDateEver.Add(time.Minute)
+	require.Equal(
+		t,
+		[]time.Time{start.Add(time.Minute * 3), start.Add(time.Minute * 4), start.Add(time.Minute * 5)},
+		triggers,
+	)
+
+	c.SetShutdownOrderLimit(2)
+	require.Len(t, c.ShutdownOrder(), 2)
+	require.Equal(t, start.Add(time.Minute*5), c.ShutdownOrder()[1].(clock.FireRecord).Trigger)
+}
+
+func TestMark(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	_, ok := c.Since("warmup")
+	require.False(t, ok)
+
+	c.Advance(time.Minute)
+	c.Mark("warmup")
+	c.Advance(time.Hour)
+	elapsed, ok := c.Since("warmup")
+	require.True(t, ok)
+	require.Equal(t, time.Hour, elapsed)
+}
+
+func TestAdvanceDidFire(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	c.NewTimer(time.Hour)
+	require.False(t, c.AdvanceDidFire(time.Minute))
+	require.True(t, c.AdvanceDidFire(time.Hour))
+	require.False(t, c.AdvanceDidFire(time.Hour))
+}
+
+func TestSpuriousWakeups(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	c.SetSpuriousWakeups(1)
+	deadline := theMostImportantDateEver.Add(time.Hour)
+	timer := c.NewTimer(time.Hour)
+
+	// a robust consumer re-checks the clock instead of trusting the wakeup.
+	wakeups := 0
+	waitForDeadline := func() {
+		for {
+			<-timer.C()
+			wakeups++
+			if !c.Now().Before(deadline) {
+				return
+			}
+			c.SetSpuriousWakeups(0)
+			c.Advance(time.Hour)
+		}
+	}
+
+	c.Advance(time.Minute)
+	waitForDeadline()
+	require.Equal(t, 2, wakeups)
+	require.False(t, c.Now().Before(deadline))
+}
+
+func TestTimeUntilQuiescent(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	_, ok := c.TimeUntilQuiescent()
+	require.False(t, ok)
+
+	c.NewTimers([]time.Duration{time.Minute, time.Hour * 3, time.Hour})
+	c.Advance(time.Minute * 30)
+	remaining, ok := c.TimeUntilQuiescent()
+	require.True(t, ok)
+	require.Equal(t, time.Hour*2+time.Minute*30, remaining)
+
+	c.Advance(remaining)
+	_, ok = c.TimeUntilQuiescent()
+	require.False(t, ok)
+}
+
+func TestTicksZeroValue(t *testing.T) {
+	t.Parallel()
+	var c clock.FakeClock
+	require.Equal(t, time.Nanosecond, c.TickDuration())
+	c.AdvanceTicks(3)
+	require.Equal(t, int64(3), c.Ticks())
+}
+
+func TestRandomizedFeaturesZeroValue(t *testing.T) {
+	t.Parallel()
+	var c clock.FakeClock
+	c.SetArmFailure(0.5)
+	c.SetSpuriousWakeups(0.5)
+	c.NewTimer(time.Hour)
+	c.AdvanceRandomly(time.Minute, 3)
+	require.Equal(t, time.Time{}.Add(time.Minute), c.Now())
+}
+
+func TestTimeoutDoneSynchronously(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	ctx, cancel := c.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	c.Advance(time.Minute)
+	require.Equal(t, 0, c.CallbacksInFlight())
+
+	// no waiting: the deadline must already have been applied when Advance returns.
+	select {
+	case <-ctx.Done():
+		require.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+	default:
+		require.Fail(t, "context should be done as soon as Advance returns")
+	}
+}
+
+func TestSpuriousWakeupReplacedByTrigger(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	c.SetSpuriousWakeups(1)
+	timer := c.NewTimer(time.Hour)
+	c.Advance(time.Minute)
+	c.Advance(time.Minute)
+
+	// advancing past the trigger without draining the spurious value must not block.
+	c.Advance(time.Hour)
+	require.Equal(t, theMostImportantDateEver.Add(time.Hour), <-timer.C())
+	ensureNotTriggered(t, timer)
+}
+
+func TestCancelAllContextsNilCause(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	ctx, cancel := c.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	c.CancelAllContexts(nil)
+	<-ctx.Done()
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestAdvanceRandomlyMaxDuration(t *testing.T) {
+	t.Parallel()
+	var c clock.FakeClock
+	c.AdvanceRandomly(math.MaxInt64, 4)
+	require.Equal(t, time.Time{}.Add(math.MaxInt64), c.Now())
+}
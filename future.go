@@ -0,0 +1,71 @@
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Future holds a value that is set once and may be awaited by any number of goroutines. Timeouts are measured by the
+// Future's Clock. It is safe for concurrent use. The zero value is ready to use and measures timeouts with RealClock.
+type Future[T any] struct {
+	clock Clock
+	mux   sync.Mutex
+	done  chan struct{}
+	set   bool
+	value T
+}
+
+// Set resolves the future with value. Only the first call to Set has any effect.
+func (f *Future[T]) Set(value T) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if f.set {
+		return
+	}
+	f.set = true
+	f.value = value
+	close(f.doneLocked())
+}
+
+// Get waits for the future to be resolved and returns its value, or returns ctx's error if ctx is done first.
+func (f *Future[T]) Get(ctx context.Context) (T, error) {
+	f.mux.Lock()
+	done := f.doneLocked()
+	f.mux.Unlock()
+
+	select {
+	case <-done:
+		return f.value, nil
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// GetTimeout is like Get, but gives up with context.DeadlineExceeded once d has elapsed on the future's clock.
+func (f *Future[T]) GetTimeout(d time.Duration) (T, error) {
+	clk := f.clock
+	if clk == nil {
+		clk = RealClock{}
+	}
+	ctx, cancel := clk.WithTimeout(context.Background(), d)
+	defer cancel()
+	return f.Get(ctx)
+}
+
+// doneLocked returns the channel closed by Set, creating it if needed. The caller must hold f.mux.
+func (f *Future[T]) doneLocked() chan struct{} {
+	if f.done == nil {
+		f.done = make(chan struct{})
+	}
+	return f.done
+}
+
+// NewFuture creates an unresolved future whose GetTimeout measures time with clk.
+func NewFuture[T any](clk Clock) *Future[T] {
+	return &Future[T]{
+		clock: clk,
+		done:  make(chan struct{}),
+	}
+}
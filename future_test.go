@@ -0,0 +1,70 @@
+package clock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/plan42-ai/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFutureResolved(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	f := clock.NewFuture[int](c)
+	go f.Set(42)
+	go f.Set(7)
+
+	value, err := f.Get(t.Context())
+	require.NoError(t, err)
+	value2, err := f.GetTimeout(time.Second)
+	require.NoError(t, err)
+	require.Equal(t, value, value2)
+	require.Contains(t, []int{42, 7}, value)
+}
+
+func TestFutureTimedOut(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	f := clock.NewFuture[string](c)
+	type result struct {
+		value string
+		err   error
+	}
+	results := make(chan result, 1)
+	go func() {
+		value, err := f.GetTimeout(time.Minute)
+		results <- result{value: value, err: err}
+	}()
+
+	// advance until the waiter's timeout has been armed and has fired.
+	require.Eventually(
+		t,
+		func() bool {
+			return c.AdvanceDidFire(time.Minute)
+		},
+		time.Second,
+		time.Millisecond,
+	)
+	r := <-results
+	require.ErrorIs(t, r.err, context.DeadlineExceeded)
+	require.Empty(t, r.value)
+
+	f.Set("too late")
+	value, err := f.Get(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "too late", value)
+}
+
+func TestFutureZeroValue(t *testing.T) {
+	t.Parallel()
+	var f clock.Future[int]
+	_, err := f.GetTimeout(time.Millisecond)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	go f.Set(42)
+	value, err := f.Get(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, 42, value)
+}
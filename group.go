@@ -0,0 +1,105 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// ClockGroup coordinates several FakeClocks that share a single master timeline. Each member clock has a fixed skew
+// relative to the master timeline, which models a distributed system where every node's clock advances at the same
+// rate but wall clock times differ. Timers on a member clock fire according to that clock's own perceived time.
+type ClockGroup struct {
+	mux            sync.Mutex
+	now            time.Time
+	members        []groupMember
+	coalesceWindow time.Duration
+}
+
+type groupMember struct {
+	clock *FakeClock
+	skew  time.Duration
+}
+
+// Now returns the current time on the group's master timeline.
+func (g *ClockGroup) Now() time.Time {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	return g.now
+}
+
+// AddClock creates a new member clock whose time is offset from the master timeline by skew.
+func (g *ClockGroup) AddClock(skew time.Duration) *FakeClock {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	c := NewFakeClock(g.now.Add(skew))
+	g.members = append(g.members, groupMember{clock: c, skew: skew})
+	return c
+}
+
+// SetCoalesceWindow enables cross-clock timer coalescing, which models devices that batch their wakeups. Whenever a
+// timer on any member clock fires during Advance, every timer on every member clock that is due within window of that
+// moment fires along with it, rather than at its own trigger time. This trades per-clock timing precision for fewer
+// distinct wakeups: coalesced timers fire up to window early, as measured on the master timeline. Deadlines of
+// contexts created with WithTimeout are never coalesced, so a context only expires once its own clock reaches its
+// deadline, keeping Deadline and CompletedAt consistent. A window of zero disables coalescing.
+func (g *ClockGroup) SetCoalesceWindow(window time.Duration) {
+	if window < 0 {
+		panic("coalesce window cannot be negative")
+	}
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	g.coalesceWindow = window
+}
+
+// Advance moves the master timeline and every member clock forward by d. Member clocks should only be advanced
+// through the group so that their skews are preserved.
+func (g *ClockGroup) Advance(d time.Duration) {
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	if d < 0 {
+		panic("time cannot move backwards")
+	}
+	target := g.now.Add(d)
+
+	if g.coalesceWindow > 0 {
+		// step through each wakeup on the merged timeline, firing everything within the window at once.
+		for next, ok := g.nextTrigger(); ok && !next.After(target); next, ok = g.nextTrigger() {
+			g.advanceMembers(next.Sub(g.now))
+			for _, m := range g.members {
+				m.clock.fireEarly(next.Add(m.skew + g.coalesceWindow))
+			}
+		}
+	}
+	g.advanceMembers(target.Sub(g.now))
+}
+
+func (g *ClockGroup) advanceMembers(d time.Duration) {
+	g.now = g.now.Add(d)
+	for _, m := range g.members {
+		m.clock.Advance(d)
+	}
+}
+
+// nextTrigger returns the earliest pending trigger across all member clocks, on the master timeline.
+func (g *ClockGroup) nextTrigger() (time.Time, bool) {
+	var ret time.Time
+	found := false
+	for _, m := range g.members {
+		trigger, ok := m.clock.nextTrigger()
+		if !ok {
+			continue
+		}
+		trigger = trigger.Add(-m.skew)
+		if !found || trigger.Before(ret) {
+			ret = trigger
+			found = true
+		}
+	}
+	return ret, found
+}
+
+func NewClockGroup(now time.Time) *ClockGroup {
+	return &ClockGroup{
+		now: now,
+	}
+}
@@ -0,0 +1,102 @@
+package clock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/plan42-ai/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClockGroupSkew(t *testing.T) {
+	t.Parallel()
+	g := clock.NewClockGroup(theMostImportantDateEver)
+	behind := g.AddClock(-time.Minute)
+	ahead := g.AddClock(time.Minute * 4)
+	require.Equal(t, theMostImportantDateEver.Add(-time.Minute), behind.Now())
+	require.Equal(t, theMostImportantDateEver.Add(time.Minute*4), ahead.Now())
+
+	// relative timers fire together, because both clocks advance at the same rate.
+	behindTimer := behind.NewTimer(time.Hour)
+	aheadTimer := ahead.NewTimer(time.Hour)
+	g.Advance(time.Hour - time.Second)
+	ensureNotTriggered(t, behindTimer)
+	ensureNotTriggered(t, aheadTimer)
+	g.Advance(time.Second)
+	ensureTriggered(t, behindTimer)
+	ensureTriggered(t, aheadTimer)
+	require.Equal(t, time.Minute*5, ahead.Now().Sub(behind.Now()))
+	require.Equal(t, theMostImportantDateEver.Add(time.Hour), g.Now())
+
+	// timers for the same absolute instant fire according to each clock's perceived time.
+	instant := g.Now().Add(time.Hour)
+	behindTimer = behind.NewTimer(instant.Sub(behind.Now()))
+	aheadTimer = ahead.NewTimer(instant.Sub(ahead.Now()))
+	g.Advance(time.Minute * 56)
+	ensureNotTriggered(t, behindTimer)
+	ensureTriggered(t, aheadTimer)
+	g.Advance(time.Minute * 5)
+	ensureTriggered(t, behindTimer)
+}
+
+func TestClockGroupCoalescing(t *testing.T) {
+	t.Parallel()
+	g := clock.NewClockGroup(theMostImportantDateEver)
+	g.SetCoalesceWindow(time.Second * 5)
+	first := g.AddClock(0)
+	second := g.AddClock(time.Second * 10)
+
+	firstTimer := first.NewTimer(time.Minute)
+	nearTimer := second.NewTimer(time.Minute + time.Second*3)
+	farTimer := second.NewTimer(time.Minute + time.Second*6)
+
+	g.Advance(time.Minute)
+	ensureTriggered(t, firstTimer)
+	ensureTriggered(t, nearTimer)
+	ensureNotTriggered(t, farTimer)
+	require.Equal(t, theMostImportantDateEver.Add(time.Minute), g.Now())
+	require.Equal(t, theMostImportantDateEver.Add(time.Minute+time.Second*10), second.Now())
+
+	g.Advance(time.Second * 6)
+	ensureTriggered(t, farTimer)
+}
+
+func TestClockGroupWithoutCoalescing(t *testing.T) {
+	t.Parallel()
+	g := clock.NewClockGroup(theMostImportantDateEver)
+	first := g.AddClock(0)
+	second := g.AddClock(time.Second * 10)
+
+	firstTimer := first.NewTimer(time.Minute)
+	nearTimer := second.NewTimer(time.Minute + time.Second*3)
+
+	g.Advance(time.Minute)
+	ensureTriggered(t, firstTimer)
+	ensureNotTriggered(t, nearTimer)
+}
+
+func TestClockGroupCoalescingSkipsContextDeadlines(t *testing.T) {
+	t.Parallel()
+	g := clock.NewClockGroup(theMostImportantDateEver)
+	g.SetCoalesceWindow(time.Second * 5)
+	first := g.AddClock(0)
+	second := g.AddClock(time.Second * 10)
+
+	firstTimer := first.NewTimer(time.Minute)
+	ctx, cancel := second.WithTimeout(context.Background(), time.Minute+time.Second*3)
+	defer cancel()
+
+	g.Advance(time.Minute)
+	ensureTriggered(t, firstTimer)
+	require.NoError(t, ctx.Err())
+
+	g.Advance(time.Second * 3)
+	<-ctx.Done()
+	require.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+	completedAt, ok := ctx.(*clock.FakeDeadlineContext).CompletedAt()
+	require.True(t, ok)
+	deadline, _ := ctx.Deadline()
+	require.Equal(t, deadline, completedAt)
+	require.Equal(t, second.Now(), completedAt)
+}
@@ -0,0 +1,98 @@
+package clock
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// LeakCheckingClock decorates a Clock, including RealClock, and reports timers that are neither stopped nor fired
+// within a grace period of becoming due. Every timer it creates captures its creation stack and holds a real timer
+// until the check runs, so it adds measurable overhead to timer creation.
+type LeakCheckingClock struct {
+	base   Clock
+	grace  time.Duration
+	onLeak func(stack string)
+}
+
+func (l *LeakCheckingClock) Now() time.Time {
+	return l.base.Now()
+}
+
+func (l *LeakCheckingClock) NewTimer(d time.Duration) Timer {
+	return l.track(l.base.NewTimer(d), d)
+}
+
+func (l *LeakCheckingClock) AfterFunc(d time.Duration, f func()) Timer {
+	return l.track(l.base.AfterFunc(d, f), d)
+}
+
+func (l *LeakCheckingClock) WithTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	return l.base.WithTimeout(parent, d)
+}
+
+func (l *LeakCheckingClock) track(timer Timer, d time.Duration) Timer {
+	ret := &leakCheckedTimer{
+		Timer:   timer,
+		clock:   l,
+		trigger: l.base.Now().Add(d),
+		stack:   string(debug.Stack()),
+	}
+	ret.graceTimer = time.AfterFunc(l.checkDelay(d), ret.check)
+	return ret
+}
+
+// checkDelay returns how long to wait, in real time, before checking a timer with duration d for a leak.
+func (l *LeakCheckingClock) checkDelay(d time.Duration) time.Duration {
+	return max(d, 0) + l.grace
+}
+
+type leakCheckedTimer struct {
+	Timer
+	clock      *LeakCheckingClock
+	mux        sync.Mutex
+	trigger    time.Time
+	stopped    bool
+	stack      string
+	graceTimer *time.Timer
+}
+
+func (t *leakCheckedTimer) Stop() bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.stopped = true
+	t.graceTimer.Stop()
+	return t.Timer.Stop()
+}
+
+func (t *leakCheckedTimer) Reset(d time.Duration) bool {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.stopped = false
+	t.trigger = t.clock.base.Now().Add(d)
+	// a re-armed timer gets a fresh check.
+	t.graceTimer.Reset(t.clock.checkDelay(d))
+	return t.Timer.Reset(d)
+}
+
+func (t *leakCheckedTimer) check() {
+	t.mux.Lock()
+	leaked := !t.stopped && t.clock.base.Now().Before(t.trigger)
+	t.mux.Unlock()
+
+	if leaked {
+		t.clock.onLeak(t.stack)
+	}
+}
+
+// NewLeakCheckingClock wraps base so that onLeak is called with the creation stack of any timer that has been neither
+// stopped nor fired once the timer's duration plus grace has elapsed in real time since it was created or last reset.
+// Timer durations are waited in real time even when base is a FakeClock.
+func NewLeakCheckingClock(base Clock, grace time.Duration, onLeak func(stack string)) *LeakCheckingClock {
+	return &LeakCheckingClock{
+		base:   base,
+		grace:  grace,
+		onLeak: onLeak,
+	}
+}
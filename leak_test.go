@@ -0,0 +1,83 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/plan42-ai/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeakCheckingClock(t *testing.T) {
+	t.Parallel()
+	base := clock.NewFakeClock(theMostImportantDateEver)
+	leaks := make(chan string, 3)
+	c := clock.NewLeakCheckingClock(
+		base, 10*time.Millisecond, func(stack string) {
+			leaks <- stack
+		},
+	)
+
+	stopped := c.NewTimer(time.Millisecond)
+	stopped.Stop()
+	fired := c.AfterFunc(time.Millisecond, func() {})
+	base.Advance(time.Millisecond)
+	leaked := c.NewTimer(time.Millisecond)
+
+	select {
+	case stack := <-leaks:
+		require.Contains(t, stack, "TestLeakCheckingClock")
+	case <-time.After(time.Second):
+		require.Fail(t, "leaked timer was not reported")
+	}
+
+	select {
+	case <-leaks:
+		require.Fail(t, "only the leaked timer should be reported")
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.False(t, fired.Stop())
+	require.True(t, leaked.Stop())
+}
+
+func TestLeakCheckingClockReset(t *testing.T) {
+	t.Parallel()
+	base := clock.NewFakeClock(theMostImportantDateEver)
+	leaks := make(chan string, 1)
+	c := clock.NewLeakCheckingClock(
+		base, 10*time.Millisecond, func(stack string) {
+			leaks <- stack
+		},
+	)
+
+	timer := c.NewTimer(time.Millisecond)
+	base.Advance(time.Millisecond)
+	<-timer.C()
+	time.Sleep(30 * time.Millisecond)
+	require.Empty(t, leaks)
+
+	// re-arming the timer and then abandoning it is a leak too.
+	timer.Reset(time.Millisecond)
+	select {
+	case stack := <-leaks:
+		require.Contains(t, stack, "TestLeakCheckingClockReset")
+	case <-time.After(time.Second):
+		require.Fail(t, "leaked timer was not reported after reset")
+	}
+}
+
+func TestLeakCheckingClockPendingTimer(t *testing.T) {
+	t.Parallel()
+	leaks := make(chan string, 1)
+	c := clock.NewLeakCheckingClock(
+		clock.NewRealClock(), 10*time.Millisecond, func(stack string) {
+			leaks <- stack
+		},
+	)
+
+	// a timer that is still legitimately pending after the grace period is not a leak.
+	timer := c.NewTimer(time.Second)
+	time.Sleep(50 * time.Millisecond)
+	require.Empty(t, leaks)
+	require.True(t, timer.Stop())
+}
@@ -0,0 +1,29 @@
+package clock
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const queryAttempts = 3
+
+// QueryWithDeadline runs op with a context that expires after d, as measured by clk. If op fails because the deadline
+// was exceeded, it is retried with a fresh deadline, up to a total of 3 attempts. Any other error is returned
+// immediately.
+func QueryWithDeadline(clk Clock, d time.Duration, op func(ctx context.Context) error) error {
+	var err error
+	for range queryAttempts {
+		err = queryOnce(clk, d, op)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+	}
+	return err
+}
+
+func queryOnce(clk Clock, d time.Duration, op func(ctx context.Context) error) error {
+	ctx, cancel := clk.WithTimeout(context.Background(), d)
+	defer cancel()
+	return op(ctx)
+}
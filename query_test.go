@@ -0,0 +1,61 @@
+package clock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/plan42-ai/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryWithDeadlineTimesOut(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	attempts := make(chan struct{})
+	result := make(chan error, 1)
+	go func() {
+		result <- clock.QueryWithDeadline(
+			c, time.Second, func(ctx context.Context) error {
+				attempts <- struct{}{}
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		)
+	}()
+
+	for range 3 {
+		<-attempts
+		c.Advance(time.Second)
+	}
+	require.ErrorIs(t, <-result, context.DeadlineExceeded)
+}
+
+func TestQueryWithDeadlineRetriesOnlyTimeouts(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	failure := errors.New("connection refused")
+	calls := 0
+	err := clock.QueryWithDeadline(
+		c, time.Second, func(_ context.Context) error {
+			calls++
+			return failure
+		},
+	)
+	require.ErrorIs(t, err, failure)
+	require.Equal(t, 1, calls)
+
+	calls = 0
+	err = clock.QueryWithDeadline(
+		c, time.Second, func(_ context.Context) error {
+			calls++
+			if calls == 1 {
+				return context.DeadlineExceeded
+			}
+			return nil
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+}
@@ -0,0 +1,90 @@
+package clock
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// assertionWindow bounds how long, in real time, the context assertions wait for a fake context to become done.
+const assertionWindow = 250 * time.Millisecond
+
+// OnceCallback returns a callback suitable for AfterFunc, along with an assertion that fails the test unless the
+// callback ran exactly once.
+func OnceCallback() (fn func(), assertRan func(tb testing.TB)) {
+	var calls atomic.Int64
+	fn = func() {
+		calls.Add(1)
+	}
+	assertRan = func(tb testing.TB) {
+		tb.Helper()
+		if n := calls.Load(); n != 1 {
+			tb.Errorf("expected callback to run exactly once, but it ran %d times", n)
+		}
+	}
+	return fn, assertRan
+}
+
+// AssertDeterministic runs scenario twice, each time against a fresh FakeClock seeded with seed, and fails the test if
+// the order in which timers fired differs between the two runs.
+func AssertDeterministic(tb testing.TB, seed uint64, scenario func(*FakeClock)) {
+	tb.Helper()
+	run := func() []FireRecord {
+		c := NewFakeClock(time.Unix(0, 0).UTC())
+		c.SetSeed(seed)
+		c.SetFireOrderLogging(true)
+		scenario(c)
+		return c.FireOrderLog()
+	}
+
+	first := run()
+	second := run()
+	equal := slices.EqualFunc(
+		first, second, func(lhs FireRecord, rhs FireRecord) bool {
+			return lhs.ID == rhs.ID && lhs.Trigger.Equal(rhs.Trigger)
+		},
+	)
+	if !equal {
+		tb.Errorf("timers fired in a different order across runs with seed %d:\nfirst:  %v\nsecond: %v", seed, first, second)
+	}
+}
+
+// AssertTimesOut creates a context with clk.WithTimeout(parent, d), advances clk by d, and fails the test unless the
+// context becomes done with context.DeadlineExceeded shortly afterward.
+func AssertTimesOut(tb testing.TB, clk *FakeClock, parent context.Context, d time.Duration) {
+	tb.Helper()
+	ctx, cancel := clk.WithTimeout(parent, d)
+	defer cancel()
+	clk.Advance(d)
+	assertDoneWith(tb, ctx, context.DeadlineExceeded)
+}
+
+// AssertCanceledByParent creates a context with clk.WithTimeout, cancels its parent before the timeout elapses, and
+// fails the test unless the cancellation propagates to the child context.
+func AssertCanceledByParent(tb testing.TB, clk *FakeClock, d time.Duration) {
+	tb.Helper()
+	parent, cancelParent := context.WithCancel(context.Background())
+	defer cancelParent()
+	ctx, cancel := clk.WithTimeout(parent, d)
+	defer cancel()
+	cancelParent()
+	assertDoneWith(tb, ctx, context.Canceled)
+}
+
+func assertDoneWith(tb testing.TB, ctx context.Context, expected error) {
+	tb.Helper()
+	realTimer := time.NewTimer(assertionWindow)
+	defer realTimer.Stop()
+
+	select {
+	case <-ctx.Done():
+		if err := ctx.Err(); !errors.Is(err, expected) {
+			tb.Errorf("expected context error %v, got %v", expected, err)
+		}
+	case <-realTimer.C:
+		tb.Errorf("context was not done with %v within %v", expected, assertionWindow)
+	}
+}
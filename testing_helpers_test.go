@@ -0,0 +1,101 @@
+package clock_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/plan42-ai/clock"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestOnceCallback(t *testing.T) {
+	t.Parallel()
+	fn, assertRan := clock.OnceCallback()
+
+	tb := &recordingTB{TB: t}
+	assertRan(tb)
+	require.Len(t, tb.errors, 1)
+
+	fn()
+	tb = &recordingTB{TB: t}
+	assertRan(tb)
+	require.Empty(t, tb.errors)
+
+	fn()
+	tb = &recordingTB{TB: t}
+	assertRan(tb)
+	require.Len(t, tb.errors, 1)
+}
+
+func TestAssertDeterministic(t *testing.T) {
+	t.Parallel()
+	tb := &recordingTB{TB: t}
+	clock.AssertDeterministic(
+		tb, 7, func(c *clock.FakeClock) {
+			for i := range 5 {
+				c.NewTimer(time.Minute * time.Duration(5-i))
+			}
+			c.AdvanceRandomly(time.Hour, 4)
+		},
+	)
+	require.Empty(t, tb.errors)
+}
+
+func TestAssertDeterministicDetectsNondeterminism(t *testing.T) {
+	t.Parallel()
+	tb := &recordingTB{TB: t}
+	runs := 0
+	clock.AssertDeterministic(
+		tb, 7, func(c *clock.FakeClock) {
+			// state leaking between runs changes the schedule on the second run.
+			runs++
+			c.NewTimer(time.Minute)
+			c.NewTimer(time.Minute * time.Duration(runs))
+			c.Advance(time.Hour)
+		},
+	)
+	require.Len(t, tb.errors, 1)
+}
+
+func TestAssertTimesOut(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	tb := &recordingTB{TB: t}
+	clock.AssertTimesOut(tb, c, context.Background(), time.Second)
+	require.Empty(t, tb.errors)
+	require.Equal(t, theMostImportantDateEver.Add(time.Second), c.Now())
+}
+
+func TestAssertCanceledByParent(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	tb := &recordingTB{TB: t}
+	clock.AssertCanceledByParent(tb, c, time.Hour)
+	require.Empty(t, tb.errors)
+}
+
+func TestAssertTimesOutDetectsMissingTimeout(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	parent, cancel := c.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	// the parent is canceled first, so the child reports the wrong error.
+	cancel()
+	tb := &recordingTB{TB: t}
+	clock.AssertTimesOut(tb, c, parent, time.Second)
+	require.Len(t, tb.errors, 1)
+}
@@ -0,0 +1,47 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Watchdog invokes a callback if it is not kicked at least once every interval, as measured by a Clock. It is safe for
+// concurrent use. A Watchdog must be created with NewWatchdog; the zero value is never armed, so Kick and Stop on it
+// have no effect.
+type Watchdog struct {
+	mux      sync.Mutex
+	interval time.Duration
+	timer    Timer
+	stopped  bool
+}
+
+// Kick resets the watchdog's deadline to one interval from now. Kicking a stopped watchdog has no effect.
+func (w *Watchdog) Kick() {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if w.stopped || w.timer == nil {
+		return
+	}
+	w.timer.Reset(w.interval)
+}
+
+// Stop disarms the watchdog. It returns false if the watchdog had already fired or been stopped.
+func (w *Watchdog) Stop() bool {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	w.stopped = true
+	if w.timer == nil {
+		return false
+	}
+	return w.timer.Stop()
+}
+
+// NewWatchdog creates a watchdog that calls onStarved if Kick is not called within interval. After firing, the
+// watchdog is re-armed by the next call to Kick.
+func NewWatchdog(clk Clock, interval time.Duration, onStarved func()) *Watchdog {
+	w := &Watchdog{
+		interval: interval,
+	}
+	w.timer = clk.AfterFunc(interval, onStarved)
+	return w
+}
@@ -0,0 +1,61 @@
+package clock_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/plan42-ai/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchdogFiresWhenStarved(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	fn, assertRan := clock.OnceCallback()
+	w := clock.NewWatchdog(c, time.Minute, fn)
+	c.Advance(time.Minute)
+	require.NoError(t, c.WaitCallbacks(t.Context()))
+	assertRan(t)
+	require.False(t, w.Stop())
+}
+
+func TestWatchdogKeptAlive(t *testing.T) {
+	t.Parallel()
+	c := clock.NewFakeClock(theMostImportantDateEver)
+	starved := atomic.Bool{}
+	w := clock.NewWatchdog(
+		c, time.Minute, func() {
+			starved.Store(true)
+		},
+	)
+
+	for range 10 {
+		c.Advance(time.Second * 30)
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				w.Kick()
+			}()
+		}
+		wg.Wait()
+	}
+	require.NoError(t, c.WaitCallbacks(t.Context()))
+	require.False(t, starved.Load())
+
+	require.True(t, w.Stop())
+	w.Kick()
+	c.Advance(time.Hour)
+	require.NoError(t, c.WaitCallbacks(t.Context()))
+	require.False(t, starved.Load())
+}
+
+func TestWatchdogZeroValue(t *testing.T) {
+	t.Parallel()
+	var w clock.Watchdog
+	w.Kick()
+	require.False(t, w.Stop())
+}